@@ -0,0 +1,226 @@
+// audit.go
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// JSONMap stores an arbitrary JSON object in a single text column.
+type JSONMap map[string]interface{}
+
+func (m JSONMap) Value() (driver.Value, error) {
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for JSONMap: %T", value)
+	}
+	if len(raw) == 0 {
+		*m = nil
+		return nil
+	}
+	return json.Unmarshal(raw, m)
+}
+
+// Event is an audit log entry recording a single create/update/delete/complete
+// action on an object, with a field-level before/after diff.
+type Event struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"index" json:"user_id"`
+	ObjectType  string    `gorm:"index" json:"object_type"`
+	ObjectID    uint      `gorm:"index" json:"object_id"`
+	Action      string    `json:"action"`
+	Description string    `json:"description"`
+	Diff        JSONMap   `json:"diff"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+const (
+	auditActionKey = "audit_action"
+	auditBeforeKey = "audit_before"
+	auditAfterKey  = "audit_after"
+)
+
+// AuditAction records the action name (e.g. "create", "update", "delete",
+// "complete") a /tasks handler performed, so auditMiddleware knows to log it.
+func AuditAction(c *gin.Context, action string) {
+	c.Set(auditActionKey, action)
+}
+
+// AuditBefore hands the middleware the pre-change snapshot, so it doesn't
+// need to re-read the row to compute a diff.
+func AuditBefore(c *gin.Context, before interface{}) {
+	c.Set(auditBeforeKey, before)
+}
+
+// AuditAfter hands the middleware the post-change snapshot.
+func AuditAfter(c *gin.Context, after interface{}) {
+	c.Set(auditAfterKey, after)
+}
+
+// auditMiddleware records an Event row for successful write requests under
+// /tasks/* once the handler has supplied an action (and before/after
+// snapshots) via AuditAction/AuditBefore/AuditAfter.
+func auditMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet {
+			return
+		}
+		if !strings.HasPrefix(c.FullPath(), "/tasks") {
+			return
+		}
+		if c.Writer.Status() >= 400 {
+			return
+		}
+
+		actionVal, ok := c.Get(auditActionKey)
+		if !ok {
+			return
+		}
+		action, _ := actionVal.(string)
+
+		before, _ := c.Get(auditBeforeKey)
+		after, _ := c.Get(auditAfterKey)
+
+		objectID := objectIDFromSnapshots(before, after)
+		event := Event{
+			UserID:      currentUserID(c),
+			ObjectType:  "task",
+			ObjectID:    objectID,
+			Action:      action,
+			Description: fmt.Sprintf("%s task %d", action, objectID),
+			Diff:        diffSnapshots(before, after),
+		}
+		if err := db.Create(&event).Error; err != nil {
+			// audit logging must never break the request it's observing
+			log.Printf("audit: failed to record event: %v", err)
+		}
+	}
+}
+
+func objectIDFromSnapshots(before, after interface{}) uint {
+	if t, ok := after.(Task); ok {
+		return t.ID
+	}
+	if t, ok := before.(Task); ok {
+		return t.ID
+	}
+	return 0
+}
+
+// diffSnapshots compares two JSON-marshalable values field by field and
+// returns a map of changed fields to their {"before","after"} values.
+func diffSnapshots(before, after interface{}) JSONMap {
+	beforeMap := toJSONMap(before)
+	afterMap := toJSONMap(after)
+
+	diff := JSONMap{}
+	seen := map[string]bool{}
+	for k := range beforeMap {
+		seen[k] = true
+	}
+	for k := range afterMap {
+		seen[k] = true
+	}
+
+	for field := range seen {
+		b, a := beforeMap[field], afterMap[field]
+		bJSON, _ := json.Marshal(b)
+		aJSON, _ := json.Marshal(a)
+		if string(bJSON) != string(aJSON) {
+			diff[field] = map[string]interface{}{"before": b, "after": a}
+		}
+	}
+	return diff
+}
+
+func toJSONMap(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// taskEventsListHandler returns the audit history for a single task.
+func taskEventsListHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	var events []Event
+	if err := db.Where("object_type = ? AND object_id = ?", "task", task.ID).Order("created_at DESC").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query events"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// eventsHandler lists audit events across objects, filterable and paginated
+// the same way listTasksHandler paginates tasks.
+func eventsHandler(c *gin.Context, db *gorm.DB) {
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+	page, _ := strconv.Atoi(pageStr)
+	limit, _ := strconv.Atoi(limitStr)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageSize {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	dbQuery := db.Model(&Event{}).Where("user_id = ?", currentUserID(c))
+	if objectType := c.Query("object_type"); objectType != "" {
+		dbQuery = dbQuery.Where("object_type = ?", objectType)
+	}
+	if action := c.Query("action"); action != "" {
+		dbQuery = dbQuery.Where("action = ?", action)
+	}
+
+	var total int64
+	dbQuery.Count(&total)
+
+	var events []Event
+	if err := dbQuery.Order("created_at DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"page": page, "limit": limit, "total": total, "events": events})
+}
@@ -0,0 +1,236 @@
+// auth.go
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// User model represents an account that owns tasks
+type User struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Email        string    `gorm:"uniqueIndex" json:"email" binding:"required,email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+const defaultJWTExpiry = 24 * time.Hour
+const sessionCookieName = "checkmate_session"
+
+// jwtSecret returns the signing key configured via JWT_SECRET, falling back
+// to a dev default so local runs without setup still work.
+func jwtSecret() []byte {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		log.Printf("WARNING: JWT_SECRET is not set, using an insecure default key; set JWT_SECRET before deploying")
+		secret = "dev-secret-change-me"
+	}
+	return []byte(secret)
+}
+
+type authClaims struct {
+	UserID uint `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// signToken issues a signed JWT for the given user
+func signToken(userID uint) (string, error) {
+	claims := authClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(defaultJWTExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret())
+}
+
+// parseToken validates a JWT and returns the embedded user ID
+func parseToken(tokenStr string) (uint, error) {
+	claims := &authClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtSecret(), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, errors.New("invalid token")
+	}
+	return claims.UserID, nil
+}
+
+// authMiddleware validates the Authorization: Bearer <token> header (or the
+// session cookie for browser requests) and stores the user ID on the context.
+func authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr := bearerToken(c)
+		if tokenStr == "" {
+			if cookie, err := c.Cookie(sessionCookieName); err == nil {
+				tokenStr = cookie
+			}
+		}
+		if tokenStr == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization"})
+			c.Abort()
+			return
+		}
+
+		userID, err := parseToken(tokenStr)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", userID)
+		c.Next()
+	}
+}
+
+// browserAuthMiddleware is like authMiddleware but redirects to the login
+// page instead of returning JSON, for the HTMX browser flow.
+func browserAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cookie, err := c.Cookie(sessionCookieName)
+		if err != nil || cookie == "" {
+			c.Redirect(http.StatusFound, "/login")
+			c.Abort()
+			return
+		}
+
+		userID, err := parseToken(cookie)
+		if err != nil {
+			c.Redirect(http.StatusFound, "/login")
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", userID)
+		c.Next()
+	}
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(header, "Bearer ")
+}
+
+// currentUserID pulls the authenticated user ID set by the auth middleware
+func currentUserID(c *gin.Context) uint {
+	id, _ := c.Get("userID")
+	userID, _ := id.(uint)
+	return userID
+}
+
+type registerPayload struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// registerHandler creates a new user account
+func registerHandler(c *gin.Context, db *gorm.DB) {
+	var payload registerPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		return
+	}
+
+	user := User{Email: payload.Email, PasswordHash: string(hash)}
+	if err := db.Create(&user).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": user.ID, "email": user.Email})
+}
+
+type loginPayload struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// loginHandler authenticates a user and returns a signed JWT
+func loginHandler(c *gin.Context, db *gorm.DB) {
+	var payload loginPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user User
+	if err := db.Where("email = ?", payload.Email).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(payload.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	token, err := signToken(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// renderLogin shows the HTMX login page
+func renderLogin(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.html", gin.H{})
+}
+
+// loginFormHandler authenticates the browser flow and sets a secure cookie session
+func loginFormHandler(c *gin.Context, db *gorm.DB) {
+	email := c.PostForm("email")
+	password := c.PostForm("password")
+
+	var user User
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		c.HTML(http.StatusUnauthorized, "login.html", gin.H{"Error": "invalid credentials"})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		c.HTML(http.StatusUnauthorized, "login.html", gin.H{"Error": "invalid credentials"})
+		return
+	}
+
+	token, err := signToken(user.ID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "login.html", gin.H{"Error": "failed to start session"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(sessionCookieName, token, int(defaultJWTExpiry.Seconds()), "/", "", true, true)
+	c.Redirect(http.StatusFound, "/")
+}
+
+// logoutHandler clears the session cookie
+func logoutHandler(c *gin.Context) {
+	c.SetCookie(sessionCookieName, "", -1, "/", "", true, true)
+	c.Redirect(http.StatusFound, "/login")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestSignAndParseTokenRoundTrip(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	token, err := signToken(42)
+	if err != nil {
+		t.Fatalf("signToken returned error: %v", err)
+	}
+
+	userID, err := parseToken(token)
+	if err != nil {
+		t.Fatalf("parseToken returned error: %v", err)
+	}
+	if userID != 42 {
+		t.Errorf("got user id %d, want 42", userID)
+	}
+}
+
+func TestParseTokenRejectsExpired(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	claims := authClaims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-2 * time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret())
+	if err != nil {
+		t.Fatalf("failed to sign test token: %v", err)
+	}
+
+	if _, err := parseToken(token); err == nil {
+		t.Error("parseToken accepted an expired token")
+	}
+}
+
+func TestParseTokenRejectsWrongSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret-a")
+	token, err := signToken(1)
+	if err != nil {
+		t.Fatalf("signToken returned error: %v", err)
+	}
+
+	t.Setenv("JWT_SECRET", "secret-b")
+	if _, err := parseToken(token); err == nil {
+		t.Error("parseToken accepted a token signed with a different secret")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer abc.def.ghi")
+
+	if got := bearerToken(c); got != "abc.def.ghi" {
+		t.Errorf("got %q, want %q", got, "abc.def.ghi")
+	}
+}
+
+func TestBearerTokenRejectsNonBearerScheme(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Basic abc123")
+
+	if got := bearerToken(c); got != "" {
+		t.Errorf("got %q, want empty string", got)
+	}
+}
+
+// TestAuthMiddlewareScopesUserID confirms the middleware extracts the user ID
+// from the token into the context rather than, say, trusting a client-supplied
+// header, which is what every /tasks handler relies on for per-user scoping.
+func TestAuthMiddlewareScopesUserID(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	token, err := signToken(99)
+	if err != nil {
+		t.Fatalf("signToken returned error: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(authMiddleware())
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": currentUserID(c)})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if want := `"user_id":99`; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("response %q does not contain %q", w.Body.String(), want)
+	}
+}
+
+func TestAuthMiddlewareRejectsMissingToken(t *testing.T) {
+	r := gin.New()
+	r.Use(authMiddleware())
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
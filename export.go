@@ -0,0 +1,92 @@
+// export.go
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeTasksCSV streams all matching tasks as CSV, ignoring pagination.
+func writeTasksCSV(c *gin.Context, tasks []Task) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="tasks.csv"`)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "title", "description", "status", "due_date", "created_at", "updated_at"})
+	for _, t := range tasks {
+		_ = w.Write([]string{
+			fmt.Sprintf("%d", t.ID),
+			t.Title,
+			t.Description,
+			t.Status,
+			formatDueDate(t.DueDate),
+			t.CreatedAt.Format(time.RFC3339),
+			t.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+}
+
+func formatDueDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// icsStatus maps our internal status to the RFC 5545 VTODO STATUS value.
+func icsStatus(status string) string {
+	switch status {
+	case "done":
+		return "COMPLETED"
+	case "in_progress", "running":
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// writeTasksICS emits an RFC 5545 .ics feed, one VTODO per task with a due date.
+func writeTasksICS(c *gin.Context, tasks []Task) {
+	c.Header("Content-Type", "text/calendar")
+	c.Header("Content-Disposition", `attachment; filename="tasks.ics"`)
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//checkmate//tasks//EN\r\n")
+
+	for _, t := range tasks {
+		if t.DueDate == nil {
+			continue
+		}
+		b.WriteString("BEGIN:VTODO\r\n")
+		fmt.Fprintf(&b, "UID:task-%d@checkmate\r\n", t.ID)
+		fmt.Fprintf(&b, "SUMMARY:%s\r\n", icsEscape(t.Title))
+		if t.Description != "" {
+			fmt.Fprintf(&b, "DESCRIPTION:%s\r\n", icsEscape(t.Description))
+		}
+		fmt.Fprintf(&b, "DUE:%s\r\n", t.DueDate.UTC().Format("20060102T150405Z"))
+		fmt.Fprintf(&b, "STATUS:%s\r\n", icsStatus(t.Status))
+		b.WriteString("END:VTODO\r\n")
+	}
+
+	b.WriteString("END:VCALENDAR\r\n")
+	c.String(http.StatusOK, b.String())
+}
+
+// icsEscape escapes the characters RFC 5545 requires in TEXT values.
+func icsEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\n", "\\n",
+	)
+	return replacer.Replace(s)
+}
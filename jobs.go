@@ -0,0 +1,278 @@
+// jobs.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// simulatedJobDuration is how long a run takes when there is no real work to
+// dispatch to. A future Kind-specific runner can replace this.
+const simulatedJobDuration = 5 * time.Second
+
+// jobQueue feeds the worker pool; buffered so enqueuing does not block the
+// request handler until 256 jobs are already waiting.
+var jobQueue chan uint
+
+// cancelFuncs maps a running task ID to the CancelFunc that will stop it.
+var cancelFuncs = struct {
+	mu sync.Mutex
+	m  map[uint]context.CancelFunc
+}{m: map[uint]context.CancelFunc{}}
+
+func registerCancelFunc(taskID uint, cancel context.CancelFunc) {
+	cancelFuncs.mu.Lock()
+	defer cancelFuncs.mu.Unlock()
+	cancelFuncs.m[taskID] = cancel
+}
+
+func popCancelFunc(taskID uint) (context.CancelFunc, bool) {
+	cancelFuncs.mu.Lock()
+	defer cancelFuncs.mu.Unlock()
+	cancel, ok := cancelFuncs.m[taskID]
+	delete(cancelFuncs.m, taskID)
+	return cancel, ok
+}
+
+// taskEvent describes a task state transition for SSE subscribers.
+type taskEvent struct {
+	TaskID uint   `json:"task_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+var taskEvents = struct {
+	mu   sync.Mutex
+	subs map[uint][]chan taskEvent
+}{subs: map[uint][]chan taskEvent{}}
+
+func subscribeTaskEvents(taskID uint) (<-chan taskEvent, func()) {
+	ch := make(chan taskEvent, 8)
+	taskEvents.mu.Lock()
+	taskEvents.subs[taskID] = append(taskEvents.subs[taskID], ch)
+	taskEvents.mu.Unlock()
+
+	unsubscribe := func() {
+		taskEvents.mu.Lock()
+		defer taskEvents.mu.Unlock()
+		subs := taskEvents.subs[taskID]
+		for i, c := range subs {
+			if c == ch {
+				taskEvents.subs[taskID] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+func publishTaskEvent(e taskEvent) {
+	taskEvents.mu.Lock()
+	defer taskEvents.mu.Unlock()
+	for _, ch := range taskEvents.subs[e.TaskID] {
+		select {
+		case ch <- e:
+		default:
+			// slow subscriber, drop the event rather than block workers
+		}
+	}
+}
+
+// workerPoolSize reads WORKER_POOL_SIZE, defaulting to 4.
+func workerPoolSize() int {
+	raw := os.Getenv("WORKER_POOL_SIZE")
+	if raw == "" {
+		return 4
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		log.Printf("invalid WORKER_POOL_SIZE %q, using default of 4", raw)
+		return 4
+	}
+	return n
+}
+
+// startWorkerPool launches the fixed-size pool of job workers.
+func startWorkerPool(db *gorm.DB, size int) {
+	jobQueue = make(chan uint, 256)
+	for i := 0; i < size; i++ {
+		go worker(db)
+	}
+}
+
+func worker(db *gorm.DB) {
+	for taskID := range jobQueue {
+		runJob(db, taskID)
+	}
+}
+
+// runJob marks the task running, executes it honoring cancellation, and
+// persists the final status and any error.
+func runJob(db *gorm.DB, taskID uint) {
+	// Register the cancel func BEFORE consulting CancelRequested: if we
+	// checked first and registered second, a cancelTaskHandler call landing
+	// in between would set the DB flag, find nothing in the registry to
+	// call, and be silently lost. Registering first means a concurrent
+	// cancel either (a) lands after registration and calls our cancel func
+	// directly, or (b) lands before our fresh re-read below, which then
+	// observes CancelRequested=true and cancels us ourselves. Either way
+	// cancel() is idempotent, so calling it twice is harmless.
+	ctx, cancel := context.WithCancel(context.Background())
+	registerCancelFunc(taskID, cancel)
+	defer func() {
+		popCancelFunc(taskID)
+		cancel()
+	}()
+
+	var task Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		log.Printf("job %d: failed to load task: %v", taskID, err)
+		return
+	}
+	if task.CancelRequested {
+		cancel()
+	}
+
+	if err := db.Model(&Task{}).Where("id = ?", taskID).Update("status", "running").Error; err != nil {
+		log.Printf("job %d: failed to mark running: %v", taskID, err)
+		return
+	}
+	publishTaskEvent(taskEvent{TaskID: taskID, Status: "running"})
+
+	status, jobErr := doWork(ctx)
+
+	updates := map[string]interface{}{"status": status}
+	if jobErr != nil {
+		updates["error"] = jobErr.Error()
+	} else {
+		updates["error"] = ""
+	}
+	if err := db.Model(&Task{}).Where("id = ?", taskID).Updates(updates).Error; err != nil {
+		log.Printf("job %d: failed to persist final status: %v", taskID, err)
+		return
+	}
+
+	task.Status = status
+	publishWebhookEvent("task.updated", task)
+
+	event := taskEvent{TaskID: taskID, Status: status}
+	if jobErr != nil {
+		event.Error = jobErr.Error()
+	}
+	publishTaskEvent(event)
+}
+
+// doWork simulates the task's business logic, honoring cancellation.
+func doWork(ctx context.Context) (status string, err error) {
+	select {
+	case <-ctx.Done():
+		return "cancelled", ctx.Err()
+	case <-time.After(simulatedJobDuration):
+		return "done", nil
+	}
+}
+
+// runTaskHandler enqueues a task for async execution and returns 202.
+func runTaskHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if runningStatuses[task.Status] {
+		c.JSON(http.StatusConflict, gin.H{"error": "task is already queued or running"})
+		return
+	}
+
+	// Conditional update: only the request that observes the pre-run status
+	// still in place wins the transition. This closes the window where two
+	// concurrent POST /run calls both pass the runningStatuses check above
+	// and would otherwise both enqueue the same task ID.
+	result := db.Model(&Task{}).
+		Where("id = ? AND status = ?", task.ID, task.Status).
+		Updates(map[string]interface{}{"status": "queued", "cancel_requested": false, "error": ""})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue task"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "task is already queued or running"})
+		return
+	}
+	publishTaskEvent(taskEvent{TaskID: task.ID, Status: "queued"})
+
+	jobQueue <- task.ID
+
+	c.Header("Location", fmt.Sprintf("/tasks/%d", task.ID))
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "id": task.ID})
+}
+
+// cancelTaskHandler requests cancellation of a running task and returns 202.
+func cancelTaskHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	if err := db.Model(&task).Update("cancel_requested", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to request cancellation"})
+		return
+	}
+
+	if cancel, ok := popCancelFunc(task.ID); ok {
+		cancel()
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "cancel_requested", "id": task.ID})
+}
+
+// taskEventsHandler streams task state transitions over SSE.
+func taskEventsHandler(c *gin.Context, db *gorm.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	taskID := uint(id)
+
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	events, unsubscribe := subscribeTaskEvents(taskID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("status", e)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -2,13 +2,18 @@
 package main
 
 import (
+	"context"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/Adarsh-Liju/checkmate/scheduler"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/sqlite"
@@ -17,13 +22,21 @@ import (
 
 // Task model represents a task in the system
 type Task struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	Title       string     `json:"title" binding:"required"`
-	Description string     `json:"description"`
-	Status      string     `json:"status"`
-	DueDate     *time.Time `json:"due_date"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"index" json:"user_id"`
+	Title           string     `json:"title" binding:"required"`
+	Description     string     `json:"description"`
+	Status          string     `json:"status"`
+	Kind            string     `json:"kind"`
+	DueDate         *time.Time `json:"due_date"`
+	RecurrenceRule  string     `json:"recurrence_rule"`
+	NextRunAt       *time.Time `json:"next_run_at"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	Version         int        `gorm:"default:1" json:"-"`
+	CancelRequested bool       `json:"cancel_requested"`
+	Error           string     `json:"error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // Allowed statuses
@@ -31,6 +44,18 @@ var allowedStatuses = map[string]bool{
 	"pending":     true,
 	"in_progress": true,
 	"done":        true,
+	"queued":      true,
+	"running":     true,
+	"cancelled":   true,
+	"failed":      true,
+}
+
+// runningStatuses are the statuses a background job can be in. Only
+// POST /tasks/:id/run moves a task into one (answering 202 Accepted);
+// create, PUT and PATCH reject these statuses with 400.
+var runningStatuses = map[string]bool{
+	"queued":  true,
+	"running": true,
 }
 
 func main() {
@@ -47,7 +72,7 @@ func main() {
 	}
 
 	// automigrate
-	if err := db.AutoMigrate(&Task{}); err != nil {
+	if err := db.AutoMigrate(&Task{}, &User{}, &Webhook{}, &WebhookDelivery{}, &Event{}); err != nil {
 		log.Fatalf("auto migrate failed: %v", err)
 	}
 
@@ -68,46 +93,174 @@ func main() {
 	// CORS - allow all for dev (adjust in prod)
 	r.Use(cors.Default())
 
-	// JSON API routes (preserve existing)
-	r.POST("/tasks", func(c *gin.Context) { createTaskHandler(c, db) })
-	r.GET("/tasks", func(c *gin.Context) { listTasksHandler(c, db) })
-	r.GET("/tasks/:id", func(c *gin.Context) { getTaskHandler(c, db) })
-	r.PUT("/tasks/:id", func(c *gin.Context) { updateTaskHandler(c, db) })
-	r.PATCH("/tasks/:id", func(c *gin.Context) { patchTaskHandler(c, db) })
-	r.DELETE("/tasks/:id", func(c *gin.Context) { deleteTaskHandler(c, db) })
-	r.POST("/tasks/:id/complete", func(c *gin.Context) { completeTaskHandler(c, db) })
+	// auth routes
+	r.POST("/auth/register", func(c *gin.Context) { registerHandler(c, db) })
+	r.POST("/auth/login", func(c *gin.Context) { loginHandler(c, db) })
+
+	// JSON API routes (preserve existing), now scoped to the authenticated user
+	api := r.Group("/tasks")
+	api.Use(authMiddleware())
+	api.Use(auditMiddleware(db))
+	api.POST("", func(c *gin.Context) { createTaskHandler(c, db) })
+	api.GET("", func(c *gin.Context) { listTasksHandler(c, db) })
+	api.GET("/:id", func(c *gin.Context) { getTaskHandler(c, db) })
+	api.PUT("/:id", func(c *gin.Context) { updateTaskHandler(c, db) })
+	api.PATCH("/:id", func(c *gin.Context) { patchTaskHandler(c, db) })
+	api.DELETE("/:id", func(c *gin.Context) { deleteTaskHandler(c, db) })
+	api.POST("/:id/complete", func(c *gin.Context) { completeTaskHandler(c, db) })
+	api.POST("/:id/pause", func(c *gin.Context) { pauseTaskHandler(c, db) })
+	api.POST("/:id/resume", func(c *gin.Context) { resumeTaskHandler(c, db) })
+	api.POST("/:id/run", func(c *gin.Context) { runTaskHandler(c, db) })
+	api.POST("/:id/cancel", func(c *gin.Context) { cancelTaskHandler(c, db) })
+	// GET /tasks/:id/events streams live state transitions over SSE, or
+	// returns the task's audit history as JSON for any other Accept header
+	api.GET("/:id/events", func(c *gin.Context) {
+		if c.GetHeader("Accept") == "text/event-stream" {
+			taskEventsHandler(c, db)
+			return
+		}
+		taskEventsListHandler(c, db)
+	})
+
+	// webhook subscriptions
+	webhooksAPI := r.Group("/webhooks")
+	webhooksAPI.Use(authMiddleware())
+	webhooksAPI.POST("", func(c *gin.Context) { createWebhookHandler(c, db) })
+	webhooksAPI.GET("", func(c *gin.Context) { listWebhooksHandler(c, db) })
+	webhooksAPI.GET("/:id", func(c *gin.Context) { getWebhookHandler(c, db) })
+	webhooksAPI.PUT("/:id", func(c *gin.Context) { updateWebhookHandler(c, db) })
+	webhooksAPI.DELETE("/:id", func(c *gin.Context) { deleteWebhookHandler(c, db) })
+	webhooksAPI.GET("/:id/deliveries", func(c *gin.Context) { listWebhookDeliveriesHandler(c, db) })
+	webhooksAPI.POST("/:id/deliveries/:delivery_id/redeliver", func(c *gin.Context) { redeliverWebhookDeliveryHandler(c, db) })
+
+	// audit log
+	r.GET("/events", authMiddleware(), func(c *gin.Context) { eventsHandler(c, db) })
 
 	// simple health
 	r.GET("/health", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
 
 	// --- HTML + HTMX routes ---
-	r.GET("/", func(c *gin.Context) { renderIndex(c, db) })
+	r.GET("/login", renderLogin)
+	r.POST("/login", func(c *gin.Context) { loginFormHandler(c, db) })
+	r.POST("/logout", logoutHandler)
+
+	browser := r.Group("/")
+	browser.Use(browserAuthMiddleware())
+	browser.GET("/", func(c *gin.Context) { renderIndex(c, db) })
 	// partial list for HTMX replacement
-	r.GET("/_tasks", func(c *gin.Context) { renderTasksPartial(c, db) })
+	browser.GET("/_tasks", func(c *gin.Context) { renderTasksPartial(c, db) })
 	// create via form (htmx posts form data)
-	r.POST("/_tasks", func(c *gin.Context) { createTaskFormHandler(c, db) })
+	browser.POST("/_tasks", func(c *gin.Context) { createTaskFormHandler(c, db) })
 	// complete via HTMX
-	r.POST("/_tasks/:id/complete", func(c *gin.Context) { completeTaskHTML(c, db) })
+	browser.POST("/_tasks/:id/complete", func(c *gin.Context) { completeTaskHTML(c, db) })
 	// delete via HTMX
-	r.POST("/_tasks/:id/delete", func(c *gin.Context) { deleteTaskHTML(c, db) })
+	browser.POST("/_tasks/:id/delete", func(c *gin.Context) { deleteTaskHTML(c, db) })
+
+	// start the async job worker pool
+	startWorkerPool(db, workerPoolSize())
+
+	// start the webhook event dispatchers
+	startWebhookDispatchers(db, workerPoolSize())
+
+	// recurring tasks of kind "webhook" fire a webhook event when due
+	scheduler.RegisterRunner("webhook", newWebhookRunner(db))
+
+	// start the recurring-task scheduler in the background
+	schedCtx, cancelSched := context.WithCancel(context.Background())
+	sched := scheduler.New(db, schedulerInterval())
+	go sched.Start(schedCtx)
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	log.Printf("listening on :%s, using DB: %s", port, dbPath)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("server failed: %v", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		log.Printf("listening on :%s, using DB: %s", port, dbPath)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server failed: %v", err)
+		}
+	}()
+
+	waitForShutdownSignal()
+	log.Printf("shutting down")
+	cancelSched()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown error: %v", err)
+	}
+}
+
+// schedulerInterval reads the poll interval from SCHEDULER_INTERVAL (a
+// Go duration string like "30s"), defaulting to 30 seconds.
+func schedulerInterval() time.Duration {
+	raw := os.Getenv("SCHEDULER_INTERVAL")
+	if raw == "" {
+		return 30 * time.Second
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("invalid SCHEDULER_INTERVAL %q, using 30s default", raw)
+		return 30 * time.Second
+	}
+	return d
+}
+
+// waitForShutdownSignal blocks until SIGINT or SIGTERM is received.
+func waitForShutdownSignal() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+}
+
+// pauseTaskHandler stops a recurring task from being scheduled again
+func pauseTaskHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if err := db.Model(&task).Update("next_run_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pause task"})
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// resumeTaskHandler recomputes NextRunAt from the task's RecurrenceRule
+func resumeTaskHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if task.RecurrenceRule == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "task has no recurrence rule"})
+		return
+	}
+	next, err := scheduler.NextOccurrence(task.RecurrenceRule, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	if err := db.Model(&task).Update("next_run_at", next).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resume task"})
+		return
+	}
+	c.JSON(http.StatusOK, task)
 }
 
 // ---------- HTML Handlers for HTMX frontend ----------
 
 func renderIndex(c *gin.Context, db *gorm.DB) {
-	// show page with first page of tasks
+	// show page with first page of tasks owned by the current user
 	var tasks []Task
-	if err := db.Order("created_at DESC").Limit(50).Find(&tasks).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).Order("created_at DESC").Limit(50).Find(&tasks).Error; err != nil {
 		c.String(http.StatusInternalServerError, "failed to load tasks")
 		return
 	}
@@ -118,7 +271,7 @@ func renderIndex(c *gin.Context, db *gorm.DB) {
 
 func renderTasksPartial(c *gin.Context, db *gorm.DB) {
 	var tasks []Task
-	if err := db.Order("created_at DESC").Limit(50).Find(&tasks).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).Order("created_at DESC").Limit(50).Find(&tasks).Error; err != nil {
 		c.String(http.StatusInternalServerError, "failed to load tasks")
 		return
 	}
@@ -149,6 +302,7 @@ func createTaskFormHandler(c *gin.Context, db *gorm.DB) {
 	}
 
 	task := Task{
+		UserID:      currentUserID(c),
 		Title:       title,
 		Description: description,
 		Status:      status,
@@ -162,14 +316,14 @@ func createTaskFormHandler(c *gin.Context, db *gorm.DB) {
 
 	// return the whole list partial so HTMX can swap it in (simple)
 	var tasks []Task
-	db.Order("created_at DESC").Limit(50).Find(&tasks)
+	db.Where("user_id = ?", currentUserID(c)).Order("created_at DESC").Limit(50).Find(&tasks)
 	c.HTML(http.StatusOK, "_task_row.html", gin.H{"Tasks": tasks})
 }
 
 func completeTaskHTML(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var task Task
-	if err := db.First(&task, id).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
 		c.String(http.StatusNotFound, "task not found")
 		return
 	}
@@ -180,18 +334,18 @@ func completeTaskHTML(c *gin.Context, db *gorm.DB) {
 	}
 	// return updated list partial (simple)
 	var tasks []Task
-	db.Order("created_at DESC").Limit(50).Find(&tasks)
+	db.Where("user_id = ?", currentUserID(c)).Order("created_at DESC").Limit(50).Find(&tasks)
 	c.HTML(http.StatusOK, "_task_row.html", gin.H{"Tasks": tasks})
 }
 
 func deleteTaskHTML(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
-	if err := db.Delete(&Task{}, id).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).Delete(&Task{}, id).Error; err != nil {
 		c.String(http.StatusInternalServerError, "failed to delete")
 		return
 	}
 	var tasks []Task
-	db.Order("created_at DESC").Limit(50).Find(&tasks)
+	db.Where("user_id = ?", currentUserID(c)).Order("created_at DESC").Limit(50).Find(&tasks)
 	c.HTML(http.StatusOK, "_task_row.html", gin.H{"Tasks": tasks})
 }
 
@@ -210,16 +364,39 @@ func createTaskHandler(c *gin.Context, db *gorm.DB) {
 	} else if !allowedStatuses[payload.Status] {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
 		return
+	} else if runningStatuses[payload.Status] {
+		// queued/running are only reachable through POST /tasks/:id/run, which
+		// actually enqueues the job; creating a task directly in one of these
+		// states would leave it stuck with no worker ever driving it.
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use POST /tasks/:id/run to start a task"})
+		return
 	}
 
+	payload.UserID = currentUserID(c)
 	if err := db.Create(&payload).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
 		return
 	}
 
+	publishWebhookEvent("task.created", payload)
+	AuditAction(c, "create")
+	AuditAfter(c, payload)
 	c.JSON(http.StatusCreated, payload)
 }
 
+// maxPageSize is the largest `limit` query param accepted; a larger limit
+// falls back to the default page size of 20.
+const maxPageSize = 100
+
+// sortableColumns whitelists the columns listTasksHandler accepts for sort_column.
+var sortableColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"due_date":   true,
+	"title":      true,
+	"status":     true,
+}
+
 // listTasksHandler lists tasks with simple filters and pagination
 func listTasksHandler(c *gin.Context, db *gorm.DB) {
 	// pagination
@@ -230,29 +407,78 @@ func listTasksHandler(c *gin.Context, db *gorm.DB) {
 	if page < 1 {
 		page = 1
 	}
-	if limit < 1 || limit > 100 {
+	if limit < 1 || limit > maxPageSize {
 		limit = 20
 	}
 	offset := (page - 1) * limit
 
-	// filters
-	status := c.Query("status")
-	q := c.Query("q")
+	// sorting
+	sortColumn := c.DefaultQuery("sort_column", "created_at")
+	if !sortableColumns[sortColumn] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_column"})
+		return
+	}
+	sortOrder := strings.ToLower(c.DefaultQuery("sort_order", "desc"))
+	if sortOrder != "asc" && sortOrder != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_order"})
+		return
+	}
 
-	var tasks []Task
-	dbQuery := db.Model(&Task{})
-	if status != "" {
-		dbQuery = dbQuery.Where("status = ?", status)
+	dbQuery := db.Model(&Task{}).Where("user_id = ?", currentUserID(c))
+
+	// filters
+	if statuses := c.QueryArray("status"); len(statuses) > 0 {
+		dbQuery = dbQuery.Where("status IN ?", statuses)
 	}
-	if q != "" {
+	if q := c.Query("q"); q != "" {
 		like := "%" + q + "%"
 		dbQuery = dbQuery.Where("title LIKE ? OR description LIKE ?", like, like)
 	}
 
+	var err error
+	if dbQuery, err = applyDateFilter(dbQuery, "due_date", ">=", c.Query("due_after")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_after"})
+		return
+	}
+	if dbQuery, err = applyDateFilter(dbQuery, "due_date", "<=", c.Query("due_before")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_before"})
+		return
+	}
+	if dbQuery, err = applyDateFilter(dbQuery, "created_at", ">=", c.Query("created_after")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_after"})
+		return
+	}
+	if dbQuery, err = applyDateFilter(dbQuery, "created_at", "<=", c.Query("created_before")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_before"})
+		return
+	}
+
+	dbQuery = dbQuery.Order(sortColumn + " " + sortOrder)
+
+	switch c.NegotiateFormat(gin.MIMEPlain, "text/csv", "text/calendar", gin.MIMEJSON) {
+	case "text/csv":
+		var tasks []Task
+		if err := dbQuery.Find(&tasks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query tasks"})
+			return
+		}
+		writeTasksCSV(c, tasks)
+		return
+	case "text/calendar":
+		var tasks []Task
+		if err := dbQuery.Find(&tasks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query tasks"})
+			return
+		}
+		writeTasksICS(c, tasks)
+		return
+	}
+
 	var total int64
 	dbQuery.Count(&total)
 
-	if err := dbQuery.Order("created_at DESC").Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
+	var tasks []Task
+	if err := dbQuery.Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query tasks"})
 		return
 	}
@@ -260,11 +486,24 @@ func listTasksHandler(c *gin.Context, db *gorm.DB) {
 	c.JSON(http.StatusOK, gin.H{"page": page, "limit": limit, "total": total, "tasks": tasks})
 }
 
+// applyDateFilter adds a `column op value` clause when value is a non-empty
+// RFC3339 timestamp, otherwise returns the query unchanged.
+func applyDateFilter(dbQuery *gorm.DB, column, op, value string) (*gorm.DB, error) {
+	if value == "" {
+		return dbQuery, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return dbQuery.Where(column+" "+op+" ?", t), nil
+}
+
 // getTaskHandler returns a single task
 func getTaskHandler(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var task Task
-	if err := db.First(&task, id).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
@@ -275,7 +514,7 @@ func getTaskHandler(c *gin.Context, db *gorm.DB) {
 func updateTaskHandler(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var existing Task
-	if err := db.First(&existing, id).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&existing, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
@@ -290,7 +529,12 @@ func updateTaskHandler(c *gin.Context, db *gorm.DB) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
 		return
 	}
+	if runningStatuses[payload.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use POST /tasks/:id/run to start a task"})
+		return
+	}
 
+	before := existing
 	existing.Title = payload.Title
 	existing.Description = payload.Description
 	if payload.Status != "" {
@@ -302,6 +546,10 @@ func updateTaskHandler(c *gin.Context, db *gorm.DB) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update task"})
 		return
 	}
+	publishWebhookEvent("task.updated", existing)
+	AuditAction(c, "update")
+	AuditBefore(c, before)
+	AuditAfter(c, existing)
 	c.JSON(http.StatusOK, existing)
 }
 
@@ -309,7 +557,7 @@ func updateTaskHandler(c *gin.Context, db *gorm.DB) {
 func patchTaskHandler(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var existing Task
-	if err := db.First(&existing, id).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&existing, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
@@ -320,16 +568,30 @@ func patchTaskHandler(c *gin.Context, db *gorm.DB) {
 		return
 	}
 
+	before := existing
+
 	if s, ok := payload["status"].(string); ok {
 		if !allowedStatuses[s] {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
 			return
 		}
+		if runningStatuses[s] {
+			// queued/running are only reachable through POST /tasks/:id/run,
+			// which actually enqueues the job; a bare PATCH here would leave
+			// the task stuck reporting a status no worker is driving.
+			c.JSON(http.StatusBadRequest, gin.H{"error": "use POST /tasks/:id/run to start a task"})
+			return
+		}
 	}
 
-	// ensure we don't update ID/CreatedAt
+	// strip fields the client must never be able to set directly: id/created_at
+	// are immutable, user_id is ownership (reassigning it would let a user hand
+	// their task to someone else, or steal one by targeting another user's row),
+	// and version is the optimistic-lock counter the scheduler owns.
 	delete(payload, "id")
 	delete(payload, "created_at")
+	delete(payload, "user_id")
+	delete(payload, "version")
 	if err := db.Model(&existing).Updates(payload).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch task"})
 		return
@@ -339,16 +601,29 @@ func patchTaskHandler(c *gin.Context, db *gorm.DB) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refetch task"})
 		return
 	}
+
+	publishWebhookEvent("task.updated", existing)
+	AuditAction(c, "update")
+	AuditBefore(c, before)
+	AuditAfter(c, existing)
 	c.JSON(http.StatusOK, existing)
 }
 
 // deleteTaskHandler deletes a task
 func deleteTaskHandler(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
-	if err := db.Delete(&Task{}, id).Error; err != nil {
+	var task Task
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if err := db.Delete(&task).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete task"})
 		return
 	}
+	publishWebhookEvent("task.deleted", task)
+	AuditAction(c, "delete")
+	AuditBefore(c, task)
 	c.Status(http.StatusNoContent)
 }
 
@@ -356,14 +631,19 @@ func deleteTaskHandler(c *gin.Context, db *gorm.DB) {
 func completeTaskHandler(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var task Task
-	if err := db.First(&task, id).Error; err != nil {
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&task, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
+	before := task
 	task.Status = "done"
 	if err := db.Save(&task).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete task"})
 		return
 	}
+	publishWebhookEvent("task.completed", task)
+	AuditAction(c, "complete")
+	AuditBefore(c, before)
+	AuditAfter(c, task)
 	c.JSON(http.StatusOK, task)
 }
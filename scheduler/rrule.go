@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// rrule is a minimal parsed subset of RFC 5545's RRULE: FREQ, INTERVAL and
+// BYDAY. Enough to drive DAILY/WEEKLY/MONTHLY recurrences.
+type rrule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+}
+
+// parseRRULE parses a `FREQ=...;INTERVAL=...;BYDAY=...` string.
+func parseRRULE(rule string) (*rrule, error) {
+	if rule == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	r := &rrule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rrule segment %q", part)
+		}
+		key, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			r.Freq = val
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := weekdayNames[d]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY value %q", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY":
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", r.Freq)
+	}
+	return r, nil
+}
+
+// NextOccurrence computes the next run time strictly after `from` for the
+// given RRULE string.
+func NextOccurrence(rule string, from time.Time) (time.Time, error) {
+	r, err := parseRRULE(rule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		return from.AddDate(0, 0, r.Interval), nil
+	case "MONTHLY":
+		return from.AddDate(0, r.Interval, 0), nil
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			return from.AddDate(0, 0, 7*r.Interval), nil
+		}
+		return nextWeeklyByDay(from, r.Interval, r.ByDay), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported FREQ %q", r.Freq)
+}
+
+// nextWeeklyByDay finds the next matching weekday after `from`. BYDAY matches
+// within the week `from` falls in are not subject to INTERVAL (RFC 5545
+// treats them as occurrences of the current week's instance of the rule);
+// once that week's matches are exhausted, it jumps a full `interval` weeks
+// ahead before looking again, so INTERVAL=2 genuinely skips every other week
+// rather than just widening how far ahead nextWeeklyByDay is willing to look.
+func nextWeeklyByDay(from time.Time, interval int, days []time.Weekday) time.Time {
+	sorted := append([]time.Weekday(nil), days...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return isoWeekdayOffset(sorted[i]) < isoWeekdayOffset(sorted[j])
+	})
+
+	weekStart := startOfWeek(from)
+	for _, wd := range sorted {
+		candidate := weekStart.AddDate(0, 0, isoWeekdayOffset(wd))
+		if candidate.After(from) {
+			return candidate
+		}
+	}
+
+	nextWeekStart := weekStart.AddDate(0, 0, 7*interval)
+	return nextWeekStart.AddDate(0, 0, isoWeekdayOffset(sorted[0]))
+}
+
+// startOfWeek returns the Monday (time-of-day preserved) of the week `t`
+// falls in, used as the anchor nextWeeklyByDay counts INTERVAL weeks from.
+func startOfWeek(t time.Time) time.Time {
+	return t.AddDate(0, 0, -isoWeekdayOffset(t.Weekday()))
+}
+
+// isoWeekdayOffset maps time.Weekday (Sunday=0) to a Monday=0..Sunday=6 offset.
+func isoWeekdayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
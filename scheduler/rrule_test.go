@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOccurrenceDaily(t *testing.T) {
+	from := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=DAILY", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := from.AddDate(0, 0, 1)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceDailyInterval(t *testing.T) {
+	from := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=DAILY;INTERVAL=3", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := from.AddDate(0, 0, 3)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceMonthly(t *testing.T) {
+	from := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=MONTHLY", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := from.AddDate(0, 1, 0)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceWeeklyNoByDay(t *testing.T) {
+	from := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC) // Monday
+	next, err := NextOccurrence("FREQ=WEEKLY", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := from.AddDate(0, 0, 7)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceWeeklyByDay(t *testing.T) {
+	// Monday, recurring on Monday/Wednesday/Friday: the next occurrence
+	// should be the very next Wednesday, not a full week out.
+	from := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=WEEKLY;BYDAY=MO,WE,FR", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := time.Date(2026, 7, 29, 9, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+	if next.Weekday() != time.Wednesday {
+		t.Errorf("expected Wednesday, got %v", next.Weekday())
+	}
+}
+
+func TestNextOccurrenceWeeklyByDayWrapsToNextWeek(t *testing.T) {
+	// Friday, recurring on Monday only: must wrap into the following week.
+	from := time.Date(2026, 7, 31, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=WEEKLY;BYDAY=MO", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := time.Date(2026, 8, 3, 9, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceWeeklyByDayWithInterval(t *testing.T) {
+	// Every other week on Monday, starting from a Monday with no later match
+	// left in the current week: next occurrence must skip a full extra week,
+	// landing two weeks out rather than one.
+	from := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := time.Date(2026, 8, 10, 9, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceWeeklyByDayWithIntervalSameWeekMatch(t *testing.T) {
+	// Monday, recurring every other week on Monday/Wednesday: Wednesday is
+	// still in the *current* week's instance of the rule, so INTERVAL does
+	// not push it out to the next eligible week.
+	from := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC)
+	next, err := NextOccurrence("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", from)
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := time.Date(2026, 7, 29, 9, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestNextOccurrenceInvalidRule(t *testing.T) {
+	cases := []string{
+		"",
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"not-a-rule",
+	}
+	for _, rule := range cases {
+		if _, err := NextOccurrence(rule, time.Now()); err == nil {
+			t.Errorf("NextOccurrence(%q): expected error, got nil", rule)
+		}
+	}
+}
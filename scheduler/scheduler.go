@@ -0,0 +1,115 @@
+// Package scheduler runs recurring tasks in the background, computing their
+// next occurrence from an RFC 5545 RRULE string and dispatching them to a
+// registered Runner keyed by task kind.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Runner executes a task of a given kind. Implementations are registered
+// with RegisterRunner and looked up by Task.Kind when a task comes due.
+type Runner interface {
+	Run(ctx context.Context, taskID uint) error
+}
+
+var runners = map[string]Runner{}
+
+// RegisterRunner associates a Runner with a task kind. Call during package
+// init or from main() before the scheduler starts.
+func RegisterRunner(kind string, r Runner) {
+	runners[kind] = r
+}
+
+// Scheduler polls for due tasks on a fixed interval and runs them in-process.
+type Scheduler struct {
+	db       *gorm.DB
+	interval time.Duration
+}
+
+// New creates a Scheduler that polls every interval. A zero interval
+// defaults to 30 seconds.
+func New(db *gorm.DB, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Scheduler{db: db, interval: interval}
+}
+
+// Start runs the polling loop until ctx is cancelled. Intended to be called
+// as a goroutine from main().
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("scheduler: shutting down")
+			return
+		case <-ticker.C:
+			s.runDueTasks(ctx)
+		}
+	}
+}
+
+// runDueTasks finds tasks whose NextRunAt has elapsed and executes each one.
+func (s *Scheduler) runDueTasks(ctx context.Context) {
+	var due []Task
+	now := time.Now()
+	if err := s.db.Where("next_run_at IS NOT NULL AND next_run_at <= ? AND status = ?", now, "pending").Find(&due).Error; err != nil {
+		log.Printf("scheduler: failed to query due tasks: %v", err)
+		return
+	}
+
+	for _, t := range due {
+		s.runOne(ctx, t)
+	}
+}
+
+// runOne claims a task with an optimistic-locked update, runs its Kind's
+// registered Runner, and advances NextRunAt from the RRULE.
+func (s *Scheduler) runOne(ctx context.Context, t Task) {
+	claim := s.db.Model(&Task{}).
+		Where("id = ? AND version = ?", t.ID, t.Version).
+		Updates(map[string]interface{}{"status": "in_progress", "version": t.Version + 1})
+	if claim.Error != nil {
+		log.Printf("scheduler: failed to claim task %d: %v", t.ID, claim.Error)
+		return
+	}
+	if claim.RowsAffected == 0 {
+		// another instance already claimed this task
+		return
+	}
+
+	var runErr error
+	if runner, ok := runners[t.Kind]; ok {
+		runErr = runner.Run(ctx, t.ID)
+	} else {
+		log.Printf("scheduler: no runner registered for kind %q (task %d)", t.Kind, t.ID)
+	}
+
+	next, err := NextOccurrence(t.RecurrenceRule, time.Now())
+	updates := map[string]interface{}{
+		"status":      "pending",
+		"last_run_at": time.Now(),
+	}
+	if err != nil {
+		log.Printf("scheduler: failed to compute next occurrence for task %d: %v", t.ID, err)
+		updates["status"] = "failed"
+	} else {
+		updates["next_run_at"] = next
+	}
+	if runErr != nil {
+		log.Printf("scheduler: runner failed for task %d: %v", t.ID, runErr)
+		updates["status"] = "failed"
+	}
+
+	if err := s.db.Model(&Task{}).Where("id = ?", t.ID).Updates(updates).Error; err != nil {
+		log.Printf("scheduler: failed to update task %d after run: %v", t.ID, err)
+	}
+}
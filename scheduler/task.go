@@ -0,0 +1,22 @@
+package scheduler
+
+import "time"
+
+// Task is the scheduler's view of the shared tasks table. It only maps the
+// columns the scheduler needs to claim and advance a task; the full model
+// lives in package main.
+type Task struct {
+	ID             uint       `gorm:"primaryKey"`
+	Kind           string     `gorm:"column:kind"`
+	Status         string     `gorm:"column:status"`
+	RecurrenceRule string     `gorm:"column:recurrence_rule"`
+	NextRunAt      *time.Time `gorm:"column:next_run_at"`
+	LastRunAt      *time.Time `gorm:"column:last_run_at"`
+	Version        int        `gorm:"column:version"`
+}
+
+// TableName pins Task to the existing tasks table rather than "tasks" being
+// pluralized from a different name.
+func (Task) TableName() string {
+	return "tasks"
+}
@@ -0,0 +1,428 @@
+// webhooks.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// StringList stores a []string as a JSON array in a single text column,
+// since sqlite has no native array type.
+type StringList []string
+
+func (s StringList) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+func (s *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for StringList: %T", value)
+	}
+	if len(raw) == 0 {
+		*s = nil
+		return nil
+	}
+	return json.Unmarshal(raw, s)
+}
+
+func (s StringList) contains(event string) bool {
+	for _, e := range s {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is a subscription to task lifecycle events for a single user.
+type Webhook struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	URL       string     `json:"url" binding:"required"`
+	Secret    string     `json:"secret"`
+	Events    StringList `json:"events"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery records one attempt to deliver an event to a Webhook.
+type WebhookDelivery struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	WebhookID    uint      `gorm:"index" json:"webhook_id"`
+	Event        string    `json:"event"`
+	Payload      string    `json:"payload"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody string    `json:"response_body"`
+	Attempt      int       `json:"attempt"`
+	DeliveredAt  time.Time `json:"delivered_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const maxResponseBodyLog = 4 * 1024
+
+var webhookBackoff = []time.Duration{250 * time.Millisecond, 1 * time.Second, 4 * time.Second, 16 * time.Second}
+
+// webhookSender is the function deliverWebhook calls to actually perform the
+// HTTP request; a package-level var (like webhookBackoff above) so tests can
+// swap in a double that skips the SSRF host check without touching the real
+// network.
+var webhookSender = postWebhook
+
+// webhookEvent is one task lifecycle notification waiting to be dispatched.
+type webhookEvent struct {
+	Type string `json:"event"`
+	Task Task   `json:"task"`
+}
+
+// webhookEventCh feeds the dispatcher pool; buffered so publishers never block.
+var webhookEventCh chan webhookEvent
+
+// publishWebhookEvent enqueues an event for delivery, dropping it if the
+// dispatcher pool has fallen behind rather than blocking the request.
+func publishWebhookEvent(eventType string, task Task) {
+	if webhookEventCh == nil {
+		return
+	}
+	select {
+	case webhookEventCh <- webhookEvent{Type: eventType, Task: task}:
+	default:
+		log.Printf("webhook event channel full, dropping %s for task %d", eventType, task.ID)
+	}
+}
+
+// webhookRunner is the scheduler.Runner for recurring tasks of kind
+// "webhook": when one comes due, it loads the task and publishes a
+// "task.due" event, so any subscription on that event gets notified through
+// the same dispatch/retry path task.created/task.updated already use.
+type webhookRunner struct {
+	db *gorm.DB
+}
+
+// newWebhookRunner builds the Runner registered with the scheduler in main().
+func newWebhookRunner(db *gorm.DB) *webhookRunner {
+	return &webhookRunner{db: db}
+}
+
+func (r *webhookRunner) Run(ctx context.Context, taskID uint) error {
+	var task Task
+	if err := r.db.First(&task, taskID).Error; err != nil {
+		return fmt.Errorf("webhookRunner: failed to load task %d: %w", taskID, err)
+	}
+	publishWebhookEvent("task.due", task)
+	return nil
+}
+
+// startWebhookDispatchers launches the background goroutines that match
+// events against subscribed webhooks and deliver them over HTTP.
+func startWebhookDispatchers(db *gorm.DB, n int) {
+	webhookEventCh = make(chan webhookEvent, 256)
+	for i := 0; i < n; i++ {
+		go webhookDispatcher(db)
+	}
+}
+
+func webhookDispatcher(db *gorm.DB) {
+	for evt := range webhookEventCh {
+		var hooks []Webhook
+		if err := db.Where("user_id = ? AND active = ?", evt.Task.UserID, true).Find(&hooks).Error; err != nil {
+			log.Printf("webhook dispatcher: failed to load webhooks: %v", err)
+			continue
+		}
+		for _, hook := range hooks {
+			if !hook.Events.contains(evt.Type) {
+				continue
+			}
+			deliverWebhook(db, hook, evt)
+		}
+	}
+}
+
+// deliverWebhook POSTs the event to a single webhook, retrying with
+// exponential backoff, and records each attempt as a WebhookDelivery.
+func deliverWebhook(db *gorm.DB, hook Webhook, evt webhookEvent) {
+	payload, err := json.Marshal(gin.H{
+		"event":     evt.Type,
+		"task":      evt.Task,
+		"timestamp": time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("webhook %d: failed to marshal payload: %v", hook.ID, err)
+		return
+	}
+
+	signature := signWebhookPayload(hook.Secret, payload)
+
+	attempts := len(webhookBackoff) + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		statusCode, body, err := webhookSender(hook.URL, payload, signature)
+		delivery := WebhookDelivery{
+			WebhookID:    hook.ID,
+			Event:        evt.Type,
+			Payload:      string(payload),
+			StatusCode:   statusCode,
+			ResponseBody: truncate(body, maxResponseBodyLog),
+			Attempt:      attempt,
+			DeliveredAt:  time.Now(),
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			log.Printf("webhook %d: failed to record delivery: %v", hook.ID, err)
+		}
+
+		if err == nil && statusCode >= 200 && statusCode < 300 {
+			return
+		}
+		if attempt <= len(webhookBackoff) {
+			time.Sleep(webhookBackoff[attempt-1])
+		}
+	}
+}
+
+func postWebhook(rawURL string, payload []byte, signature string) (statusCode int, body string, err error) {
+	// Re-validate right before dialing, not just at create/update time: a
+	// webhook's URL could resolve differently than it did when it was
+	// registered (DNS rebinding), so this is the check that actually matters.
+	if err := validateWebhookURL(rawURL); err != nil {
+		return 0, "", fmt.Errorf("refusing to deliver: %w", err)
+	}
+	return postWebhookWithClient(&http.Client{Timeout: 10 * time.Second}, rawURL, payload, signature)
+}
+
+// postWebhookWithClient issues the signed request with the given client,
+// without the validateWebhookURL SSRF check. Split out so tests can point it
+// at an httptest.Server (which binds to loopback) without weakening the
+// guard in postWebhook itself.
+func postWebhookWithClient(client *http.Client, rawURL string, payload []byte, signature string) (statusCode int, body string, err error) {
+	req, err := http.NewRequest(http.MethodPost, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Checkmate-Signature", "sha256="+signature)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(respBody), nil
+}
+
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+// validateWebhookURL rejects anything that isn't a public http(s) endpoint,
+// so a user can't register a webhook that makes this server issue requests
+// to loopback, link-local (including the cloud metadata address), or other
+// private-network addresses on their behalf (SSRF).
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.New("url must use http or https")
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return errors.New("url must have a host")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return errors.New("url host is not allowed")
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("could not resolve url host: %w", err)
+	}
+	for _, ip := range ips {
+		if isDisallowedWebhookIP(ip) {
+			return fmt.Errorf("url resolves to a disallowed address: %s", ip)
+		}
+	}
+	return nil
+}
+
+func isDisallowedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsMulticast()
+}
+
+// ---------- Webhook CRUD handlers ----------
+
+func createWebhookHandler(c *gin.Context, db *gorm.DB) {
+	var payload Webhook
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhookURL(payload.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payload.UserID = currentUserID(c)
+	if err := db.Create(&payload).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+	c.JSON(http.StatusCreated, payload)
+}
+
+func listWebhooksHandler(c *gin.Context, db *gorm.DB) {
+	var hooks []Webhook
+	if err := db.Where("user_id = ?", currentUserID(c)).Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": hooks})
+}
+
+func getWebhookHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var hook Webhook
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&hook, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+func updateWebhookHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var existing Webhook
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&existing, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	var payload Webhook
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhookURL(payload.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing.URL = payload.URL
+	existing.Secret = payload.Secret
+	existing.Events = payload.Events
+	existing.Active = payload.Active
+
+	if err := db.Save(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update webhook"})
+		return
+	}
+	c.JSON(http.StatusOK, existing)
+}
+
+func deleteWebhookHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	if err := db.Where("user_id = ?", currentUserID(c)).Delete(&Webhook{}, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete webhook"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// listWebhookDeliveriesHandler returns delivery attempts for a webhook, most recent first.
+func listWebhookDeliveriesHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	var hook Webhook
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&hook, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	var deliveries []WebhookDelivery
+	if err := db.Where("webhook_id = ?", hook.ID).Order("delivered_at DESC").Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// redeliverWebhookDeliveryHandler replays a previous delivery's payload against the webhook.
+func redeliverWebhookDeliveryHandler(c *gin.Context, db *gorm.DB) {
+	id := c.Param("id")
+	deliveryID := c.Param("delivery_id")
+
+	var hook Webhook
+	if err := db.Where("user_id = ?", currentUserID(c)).First(&hook, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	var original WebhookDelivery
+	if err := db.Where("webhook_id = ?", hook.ID).First(&original, deliveryID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return
+	}
+
+	signature := signWebhookPayload(hook.Secret, []byte(original.Payload))
+	statusCode, body, err := postWebhook(hook.URL, []byte(original.Payload), signature)
+
+	delivery := WebhookDelivery{
+		WebhookID:    hook.ID,
+		Event:        original.Event,
+		Payload:      original.Payload,
+		StatusCode:   statusCode,
+		ResponseBody: truncate(body, maxResponseBodyLog),
+		Attempt:      original.Attempt + 1,
+		DeliveredAt:  time.Now(),
+	}
+	if createErr := db.Create(&delivery).Error; createErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record redelivery"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "delivery": delivery})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSignWebhookPayloadIsDeterministic(t *testing.T) {
+	payload := []byte(`{"event":"task.updated"}`)
+
+	sig1 := signWebhookPayload("secret", payload)
+	sig2 := signWebhookPayload("secret", payload)
+	if sig1 != sig2 {
+		t.Errorf("same secret and payload produced different signatures: %q vs %q", sig1, sig2)
+	}
+
+	if other := signWebhookPayload("different-secret", payload); other == sig1 {
+		t.Error("different secrets produced the same signature")
+	}
+}
+
+func TestStringListValueScanRoundTrip(t *testing.T) {
+	original := StringList{"task.created", "task.updated"}
+
+	value, err := original.Value()
+	if err != nil {
+		t.Fatalf("Value returned error: %v", err)
+	}
+
+	var scanned StringList
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+
+	if len(scanned) != len(original) {
+		t.Fatalf("got %v, want %v", scanned, original)
+	}
+	for i := range original {
+		if scanned[i] != original[i] {
+			t.Errorf("got %v, want %v", scanned, original)
+		}
+	}
+
+	if !scanned.contains("task.created") {
+		t.Error("expected scanned list to contain task.created")
+	}
+	if scanned.contains("task.deleted") {
+		t.Error("expected scanned list not to contain task.deleted")
+	}
+}
+
+func TestStringListScanNil(t *testing.T) {
+	var s StringList
+	if err := s.Scan(nil); err != nil {
+		t.Fatalf("Scan(nil) returned error: %v", err)
+	}
+	if s != nil {
+		t.Errorf("got %v, want nil", s)
+	}
+}
+
+func TestValidateWebhookURLRejectsPrivateAndLoopback(t *testing.T) {
+	cases := []string{
+		"http://127.0.0.1/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://0.0.0.0/hook",
+		"ftp://example.com/hook",
+		"not-a-url ::",
+	}
+	for _, raw := range cases {
+		if err := validateWebhookURL(raw); err == nil {
+			t.Errorf("validateWebhookURL(%q): expected error, got nil", raw)
+		}
+	}
+}
+
+func TestValidateWebhookURLAcceptsPublicAddress(t *testing.T) {
+	if err := validateWebhookURL("http://93.184.216.34/hook"); err != nil {
+		t.Errorf("validateWebhookURL rejected a public IP literal: %v", err)
+	}
+}
+
+func TestPostWebhookRefusesDisallowedURL(t *testing.T) {
+	_, _, err := postWebhook("http://127.0.0.1:1/hook", []byte("{}"), "sig")
+	if err == nil {
+		t.Error("postWebhook should refuse to dial a loopback address")
+	}
+}
+
+func TestDeliverWebhookRetriesUntilSuccess(t *testing.T) {
+	origBackoff := webhookBackoff
+	webhookBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { webhookBackoff = origBackoff }()
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		sig := r.Header.Get("X-Checkmate-Signature")
+		if sig == "" {
+			t.Error("request missing signature header")
+		}
+		if attempts < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// httptest.Server listens on loopback, which validateWebhookURL (rightly)
+	// refuses to dial in production; swap in a sender that talks to it
+	// directly so this test exercises retry/backoff and signature wiring
+	// rather than the SSRF guard, which has its own tests above.
+	origSender := webhookSender
+	webhookSender = func(url string, payload []byte, signature string) (int, string, error) {
+		return postWebhookWithClient(server.Client(), url, payload, signature)
+	}
+	defer func() { webhookSender = origSender }()
+
+	db := newTestDB(t)
+	hook := Webhook{URL: server.URL, Secret: "secret", Active: true, Events: StringList{"task.updated"}}
+	if err := db.Create(&hook).Error; err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+
+	deliverWebhook(db, hook, webhookEvent{Type: "task.updated", Task: Task{ID: 1}})
+
+	if attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+
+	var deliveries []WebhookDelivery
+	if err := db.Where("webhook_id = ?", hook.ID).Order("id ASC").Find(&deliveries).Error; err != nil {
+		t.Fatalf("failed to query deliveries: %v", err)
+	}
+	if len(deliveries) != 3 {
+		t.Fatalf("got %d recorded deliveries, want 3", len(deliveries))
+	}
+	if deliveries[len(deliveries)-1].StatusCode != http.StatusOK {
+		t.Errorf("last delivery status code = %d, want 200", deliveries[len(deliveries)-1].StatusCode)
+	}
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&Webhook{}, &WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+	return db
+}